@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// eventShape identifies which Lambda trigger produced a request payload.
+type eventShape int
+
+const (
+	shapeAPIGatewayV1 eventShape = iota
+	shapeAPIGatewayV2
+	shapeALB
+)
+
+// payloadSniff is unmarshaled just far enough to tell an API Gateway v2
+// (HTTP API), ALB, or v1 (REST API) payload apart, without committing to
+// any one of their full request types.
+type payloadSniff struct {
+	Version        string `json:"version"`
+	RequestContext struct {
+		ELB *json.RawMessage `json:"elb"`
+	} `json:"requestContext"`
+}
+
+func detectEventShape(raw json.RawMessage) (eventShape, error) {
+	var sniff payloadSniff
+	if err := json.Unmarshal(raw, &sniff); err != nil {
+		return shapeAPIGatewayV1, fmt.Errorf("server: unrecognized Lambda event payload: %w", err)
+	}
+	if sniff.RequestContext.ELB != nil {
+		return shapeALB, nil
+	}
+	if sniff.Version == "2.0" {
+		return shapeAPIGatewayV2, nil
+	}
+	return shapeAPIGatewayV1, nil
+}
+
+// dispatch is the single handler registered with lambda.Start. It sniffs
+// the incoming payload shape, normalizes it to the canonical
+// events.APIGatewayProxyRequest form that handleLambdaRequest and the rest
+// of Server already understand, and translates the response back to
+// whatever shape the trigger expects. The return type is interface{}
+// because the three triggers expect different response JSON shapes. ctx is
+// lambda.Start's real invocation context, carrying the Lambda deadline and
+// invocation metadata through to handleLambdaRequest unchanged.
+func (s *Server) dispatch(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	shape, err := detectEventShape(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	switch shape {
+	case shapeAPIGatewayV2:
+		var v2Req events.APIGatewayV2HTTPRequest
+		if err := json.Unmarshal(raw, &v2Req); err != nil {
+			return nil, fmt.Errorf("server: decoding API Gateway v2 request: %w", err)
+		}
+		resp, err := s.handleLambdaRequest(ctx, translateV2Request(v2Req))
+		if err != nil {
+			return nil, err
+		}
+		return translateResponseToV2(resp), nil
+
+	case shapeALB:
+		var albReq events.ALBTargetGroupRequest
+		if err := json.Unmarshal(raw, &albReq); err != nil {
+			return nil, fmt.Errorf("server: decoding ALB target group request: %w", err)
+		}
+		resp, err := s.handleLambdaRequest(ctx, translateALBRequest(albReq))
+		if err != nil {
+			return nil, err
+		}
+		return translateResponseToALB(resp), nil
+
+	default:
+		var v1Req events.APIGatewayProxyRequest
+		if err := json.Unmarshal(raw, &v1Req); err != nil {
+			return nil, fmt.Errorf("server: decoding API Gateway v1 request: %w", err)
+		}
+		return s.handleLambdaRequest(ctx, v1Req)
+	}
+}
+
+// translateV2Request normalizes an HTTP API (payload format 2.0) request
+// into the v1 shape the rest of Server is built around. RequestContext is
+// carried over too (not just method/path/body/headers): handleLambdaRequest
+// reads RequestContext.RequestID as its primary request-ID source, and the
+// structured logging it feeds keys every record on that ID, so dropping it
+// here would make v2 deployments log against the Lambda invocation ID
+// instead of API Gateway's own requestId.
+func translateV2Request(req events.APIGatewayV2HTTPRequest) events.APIGatewayProxyRequest {
+	path := req.RawPath
+	if path == "" {
+		path = req.RequestContext.HTTP.Path
+	}
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:            req.RequestContext.HTTP.Method,
+		Path:                  path,
+		Body:                  req.Body,
+		Headers:               req.Headers,
+		QueryStringParameters: req.QueryStringParameters,
+		IsBase64Encoded:       req.IsBase64Encoded,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			AccountID:        req.RequestContext.AccountID,
+			Stage:            req.RequestContext.Stage,
+			DomainName:       req.RequestContext.DomainName,
+			DomainPrefix:     req.RequestContext.DomainPrefix,
+			RequestID:        req.RequestContext.RequestID,
+			Path:             path,
+			HTTPMethod:       req.RequestContext.HTTP.Method,
+			RequestTime:      req.RequestContext.Time,
+			RequestTimeEpoch: req.RequestContext.TimeEpoch,
+			APIID:            req.RequestContext.APIID,
+		},
+	}
+}
+
+// translateALBRequest normalizes an ALB target group request into the v1
+// shape, flattening multi-value headers/query parameters when ALB's
+// "multi value headers" target group attribute is enabled.
+func translateALBRequest(req events.ALBTargetGroupRequest) events.APIGatewayProxyRequest {
+	headers := req.Headers
+	if headers == nil {
+		headers = flattenMultiValues(req.MultiValueHeaders)
+	}
+	query := req.QueryStringParameters
+	if query == nil {
+		query = flattenMultiValues(req.MultiValueQueryStringParameters)
+	}
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:            req.HTTPMethod,
+		Path:                  req.Path,
+		Body:                  req.Body,
+		Headers:               headers,
+		QueryStringParameters: query,
+		IsBase64Encoded:       req.IsBase64Encoded,
+	}
+}
+
+// flattenMultiValues collapses a multi-value map down to its first value per
+// key, matching how API Gateway/ALB populate the single-value maps.
+func flattenMultiValues(values map[string][]string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	flat := make(map[string]string, len(values))
+	for key, vs := range values {
+		if len(vs) > 0 {
+			flat[key] = vs[0]
+		}
+	}
+	return flat
+}
+
+// translateResponseToV2 converts the canonical v1 response into the shape
+// an HTTP API (payload format 2.0) integration expects.
+func translateResponseToV2(resp events.APIGatewayProxyResponse) events.APIGatewayV2HTTPResponse {
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode:      resp.StatusCode,
+		Headers:         resp.Headers,
+		Body:            resp.Body,
+		IsBase64Encoded: resp.IsBase64Encoded,
+	}
+}
+
+// translateResponseToALB converts the canonical v1 response into the shape
+// an ALB target group integration expects, which additionally requires a
+// StatusDescription.
+func translateResponseToALB(resp events.APIGatewayProxyResponse) events.ALBTargetGroupResponse {
+	return events.ALBTargetGroupResponse{
+		StatusCode:        resp.StatusCode,
+		StatusDescription: fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		Headers:           resp.Headers,
+		Body:              resp.Body,
+		IsBase64Encoded:   resp.IsBase64Encoded,
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestDetectEventShape(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want eventShape
+	}{
+		{"v1", `{"httpMethod":"GET","path":"/x"}`, shapeAPIGatewayV1},
+		{"v2", `{"version":"2.0","rawPath":"/x"}`, shapeAPIGatewayV2},
+		{"alb", `{"requestContext":{"elb":{"targetGroupArn":"arn"}}}`, shapeALB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shape, err := detectEventShape(json.RawMessage(tt.raw))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if shape != tt.want {
+				t.Errorf("detectEventShape(%s) = %v, want %v", tt.name, shape, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslateV2RequestCarriesRequestContext(t *testing.T) {
+	v2Req := events.APIGatewayV2HTTPRequest{
+		RawPath: "/users/42",
+		Body:    `{"ok":true}`,
+		Headers: map[string]string{"Content-Type": "application/json"},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			AccountID:    "123456789012",
+			Stage:        "prod",
+			DomainName:   "api.example.com",
+			DomainPrefix: "api",
+			RequestID:    "apigw-v2-req-id-123",
+			APIID:        "abc123",
+			Time:         "27/Jul/2026:00:00:00 +0000",
+			TimeEpoch:    1800000000,
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method: "POST",
+				Path:   "/users/42",
+			},
+		},
+	}
+
+	got := translateV2Request(v2Req)
+
+	if got.HTTPMethod != "POST" {
+		t.Errorf("HTTPMethod = %q, want %q", got.HTTPMethod, "POST")
+	}
+	if got.Path != "/users/42" {
+		t.Errorf("Path = %q, want %q", got.Path, "/users/42")
+	}
+	if got.RequestContext.RequestID != "apigw-v2-req-id-123" {
+		t.Errorf("RequestContext.RequestID = %q, want %q", got.RequestContext.RequestID, "apigw-v2-req-id-123")
+	}
+	if got.RequestContext.AccountID != "123456789012" {
+		t.Errorf("RequestContext.AccountID = %q, want %q", got.RequestContext.AccountID, "123456789012")
+	}
+	if got.RequestContext.Stage != "prod" {
+		t.Errorf("RequestContext.Stage = %q, want %q", got.RequestContext.Stage, "prod")
+	}
+	if got.RequestContext.APIID != "abc123" {
+		t.Errorf("RequestContext.APIID = %q, want %q", got.RequestContext.APIID, "abc123")
+	}
+	if got.RequestContext.RequestTime != v2Req.RequestContext.Time {
+		t.Errorf("RequestContext.RequestTime = %q, want %q", got.RequestContext.RequestTime, v2Req.RequestContext.Time)
+	}
+	if got.RequestContext.RequestTimeEpoch != v2Req.RequestContext.TimeEpoch {
+		t.Errorf("RequestContext.RequestTimeEpoch = %d, want %d", got.RequestContext.RequestTimeEpoch, v2Req.RequestContext.TimeEpoch)
+	}
+}
+
+func TestTranslateV2RequestFallsBackToRequestContextPath(t *testing.T) {
+	v2Req := events.APIGatewayV2HTTPRequest{
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method: "GET",
+				Path:   "/fallback",
+			},
+		},
+	}
+
+	got := translateV2Request(v2Req)
+	if got.Path != "/fallback" {
+		t.Errorf("Path = %q, want %q", got.Path, "/fallback")
+	}
+}
+
+func TestTranslateALBRequestFlattensMultiValues(t *testing.T) {
+	albReq := events.ALBTargetGroupRequest{
+		HTTPMethod: "GET",
+		Path:       "/users",
+		MultiValueHeaders: map[string][]string{
+			"X-Custom": {"first", "second"},
+		},
+		MultiValueQueryStringParameters: map[string][]string{
+			"tag": {"a", "b"},
+		},
+	}
+
+	got := translateALBRequest(albReq)
+
+	if got.HTTPMethod != "GET" || got.Path != "/users" {
+		t.Errorf("HTTPMethod/Path = %q/%q, want GET//users", got.HTTPMethod, got.Path)
+	}
+	if got.Headers["X-Custom"] != "first" {
+		t.Errorf("Headers[X-Custom] = %q, want %q", got.Headers["X-Custom"], "first")
+	}
+	if got.QueryStringParameters["tag"] != "a" {
+		t.Errorf("QueryStringParameters[tag] = %q, want %q", got.QueryStringParameters["tag"], "a")
+	}
+}
+
+func TestTranslateALBRequestPrefersSingleValueMaps(t *testing.T) {
+	albReq := events.ALBTargetGroupRequest{
+		HTTPMethod: "GET",
+		Path:       "/users",
+		Headers:    map[string]string{"X-Custom": "single"},
+		MultiValueHeaders: map[string][]string{
+			"X-Custom": {"multi"},
+		},
+	}
+
+	got := translateALBRequest(albReq)
+	if got.Headers["X-Custom"] != "single" {
+		t.Errorf("Headers[X-Custom] = %q, want %q (single-value map takes precedence)", got.Headers["X-Custom"], "single")
+	}
+}
+
+func TestTranslateResponseToV2AndALB(t *testing.T) {
+	resp := events.APIGatewayProxyResponse{
+		StatusCode: 201,
+		Headers:    map[string]string{"X-Test": "1"},
+		Body:       "created",
+	}
+
+	v2 := translateResponseToV2(resp)
+	if v2.StatusCode != 201 || v2.Body != "created" {
+		t.Errorf("translateResponseToV2 = %+v, want StatusCode=201 Body=created", v2)
+	}
+
+	alb := translateResponseToALB(resp)
+	if alb.StatusCode != 201 || alb.StatusDescription != "201 Created" {
+		t.Errorf("translateResponseToALB = %+v, want StatusCode=201 StatusDescription=\"201 Created\"", alb)
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig describes a CORS policy, applied either as the server-wide
+// default (Server.UseCORS) or as a per-route override (WithCORS, passed to
+// MountEndpoint). It mirrors the rs/cors model rather than hard-coding a
+// single wildcard policy.
+type CORSConfig struct {
+	// AllowedOrigins lists the origins a request's Origin header is matched
+	// against. An entry of "*" matches any origin; an entry of the form
+	// "*.example.com" matches "example.com" and any of its subdomains.
+	// Everything else is matched exactly.
+	AllowedOrigins []string
+	// AllowedHeaders lists the headers a preflight request may ask for.
+	AllowedHeaders []string
+	// ExposedHeaders lists the headers a browser is allowed to read off the
+	// actual (non-preflight) response.
+	ExposedHeaders []string
+	// AllowCredentials sets Access-Control-Allow-Credentials. Must not be
+	// combined with an AllowedOrigins entry of "*" — browsers reject that
+	// combination outright, so matched origins are always echoed back
+	// rather than answered with a literal "*".
+	AllowCredentials bool
+	// MaxAge controls how long a browser may cache a preflight response; it
+	// is sent as whole seconds. Zero omits Access-Control-Max-Age.
+	MaxAge time.Duration
+	// OptionsPassthrough, when true, leaves OPTIONS requests for this
+	// route's path to fall through to a mounted OPTIONS handler (if any)
+	// instead of being answered automatically.
+	OptionsPassthrough bool
+}
+
+// DefaultCORSConfig is applied to every route until Server.UseCORS is
+// called. Unlike the policy it replaces, it does not set
+// AllowCredentials alongside a wildcard origin.
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "Accept-Language"},
+		ExposedHeaders: []string{"Content-Length"},
+		MaxAge:         12 * time.Hour,
+	}
+}
+
+// UseCORS sets the server-wide CORS policy, used by any route that does not
+// supply its own via WithCORS.
+func (s *Server) UseCORS(cfg CORSConfig) {
+	s.corsConfig = cfg
+}
+
+// EndpointOption customizes a single MountEndpoint registration.
+type EndpointOption func(*endpointOptions)
+
+type endpointOptions struct {
+	cors *CORSConfig
+}
+
+// WithCORS overrides the server-wide CORS policy for one route.
+func WithCORS(cfg CORSConfig) EndpointOption {
+	return func(o *endpointOptions) {
+		o.cors = &cfg
+	}
+}
+
+// matchOrigin reports whether origin satisfies any of patterns.
+func matchOrigin(origin string, patterns []string) bool {
+	if origin == "" {
+		return false
+	}
+	host := hostWithoutPort(origin)
+	for _, pattern := range patterns {
+		switch {
+		case pattern == "*":
+			return true
+		case pattern == origin:
+			return true
+		case strings.HasPrefix(pattern, "*."):
+			suffix := pattern[1:] // ".example.com"
+			if host == suffix[1:] || strings.HasSuffix(host, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hostWithoutPort strips the "scheme://" prefix and any explicit port from
+// an Origin header value, since dev/staging origins routinely carry a port
+// (e.g. "https://app.example.com:3000") that would otherwise defeat a
+// "*.example.com" suffix match.
+func hostWithoutPort(origin string) string {
+	host := origin
+	if idx := strings.Index(host, "://"); idx >= 0 {
+		host = host[idx+3:]
+	}
+	if idx := strings.LastIndex(host, ":"); idx >= 0 {
+		if _, err := strconv.Atoi(host[idx+1:]); err == nil {
+			host = host[:idx]
+		}
+	}
+	return host
+}
+
+// preferredCORSMethod extracts the method a CORS preflight says the real
+// request will use, from the Access-Control-Request-Method header. It lets
+// routeMatchForPath pick the CORS policy of the route that preflight is
+// actually for, when two methods on the same path carry different
+// per-route overrides.
+func preferredCORSMethod(headers map[string]string) Method {
+	return Method(strings.ToUpper(headerValue(headers, "Access-Control-Request-Method")))
+}
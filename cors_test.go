@@ -0,0 +1,87 @@
+package server
+
+import "testing"
+
+func TestMatchOriginWildcardSuffix(t *testing.T) {
+	patterns := []string{"*.example.com"}
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"https://app.example.com:3000", true},
+		{"http://example.com:8080", true},
+		{"https://example.com", true},
+		{"https://evil.com", false},
+		{"https://notexample.com", false},
+	}
+	for _, tt := range tests {
+		if got := matchOrigin(tt.origin, patterns); got != tt.want {
+			t.Errorf("matchOrigin(%q, %v) = %v, want %v", tt.origin, patterns, got, tt.want)
+		}
+	}
+}
+
+func TestMatchOriginWildcardStar(t *testing.T) {
+	if !matchOrigin("https://anything.test", []string{"*"}) {
+		t.Error("expected \"*\" to match any origin")
+	}
+}
+
+func TestMatchOriginExact(t *testing.T) {
+	patterns := []string{"https://app.example.com"}
+	if !matchOrigin("https://app.example.com", patterns) {
+		t.Error("expected exact origin match")
+	}
+	if matchOrigin("https://app.example.com:3000", patterns) {
+		t.Error("exact match must not ignore an explicit port")
+	}
+}
+
+func TestRouteMatchForPathPrefersRequestedMethod(t *testing.T) {
+	s := newTestServer()
+	getCORS := CORSConfig{AllowedOrigins: []string{"https://a.example.com"}}
+	postCORS := CORSConfig{AllowedOrigins: []string{"https://b.example.com"}}
+
+	if err := s.MountEndpoint(GET, "/shared", noopHandler, WithCORS(getCORS)); err != nil {
+		t.Fatalf("unexpected error mounting GET /shared: %v", err)
+	}
+	if err := s.MountEndpoint(POST, "/shared", noopHandler, WithCORS(postCORS)); err != nil {
+		t.Fatalf("unexpected error mounting POST /shared: %v", err)
+	}
+
+	segments := splitSegments("/shared")
+
+	for i := 0; i < 50; i++ {
+		_, cors := s.routeMatchForPath(segments, GET)
+		if cors == nil || cors.AllowedOrigins[0] != "https://a.example.com" {
+			t.Fatalf("iteration %d: routeMatchForPath with preferred GET = %+v, want GET's policy", i, cors)
+		}
+		_, cors = s.routeMatchForPath(segments, POST)
+		if cors == nil || cors.AllowedOrigins[0] != "https://b.example.com" {
+			t.Fatalf("iteration %d: routeMatchForPath with preferred POST = %+v, want POST's policy", i, cors)
+		}
+	}
+}
+
+func TestRouteMatchForPathDeterministicWithoutPreferredMethod(t *testing.T) {
+	s := newTestServer()
+	getCORS := CORSConfig{AllowedOrigins: []string{"https://a.example.com"}}
+	postCORS := CORSConfig{AllowedOrigins: []string{"https://b.example.com"}}
+
+	if err := s.MountEndpoint(GET, "/shared", noopHandler, WithCORS(getCORS)); err != nil {
+		t.Fatalf("unexpected error mounting GET /shared: %v", err)
+	}
+	if err := s.MountEndpoint(POST, "/shared", noopHandler, WithCORS(postCORS)); err != nil {
+		t.Fatalf("unexpected error mounting POST /shared: %v", err)
+	}
+
+	segments := splitSegments("/shared")
+	_, first := s.routeMatchForPath(segments, "")
+	for i := 0; i < 50; i++ {
+		_, cors := s.routeMatchForPath(segments, "")
+		if cors != first {
+			t.Fatalf("iteration %d: routeMatchForPath(\"\") returned %+v, want stable %+v across calls", i, cors, first)
+		}
+	}
+}
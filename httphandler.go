@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// HTTPHandler returns a net/http.Handler that dispatches to the same
+// Route/HandlerFunction registrations as Lambda mode, translating each
+// *http.Request into an events.APIGatewayProxyRequest and writing the
+// resulting events.APIGatewayProxyResponse back through the
+// http.ResponseWriter. It ignores AWS_LAMBDA_FUNCTION_NAME entirely, so it
+// can be wired into httptest.NewServer (and, from there, Pact provider
+// verification) without SAM local or a deployed API Gateway in front of it.
+func (s *Server) HTTPHandler() http.Handler {
+	return http.HandlerFunc(s.serveAPIGateway)
+}
+
+func (s *Server) serveAPIGateway(w http.ResponseWriter, r *http.Request) {
+	segments := splitSegments(r.URL.Path)
+	method := Method(strings.ToUpper(r.Method))
+
+	headers := make(map[string]string, len(r.Header))
+	for key := range r.Header {
+		headers[key] = r.Header.Get(key)
+	}
+
+	if method == OPTIONS {
+		_, cors := s.routeMatchForPath(segments, preferredCORSMethod(headers))
+		if cors == nil {
+			cors = &s.corsConfig
+		}
+		if !cors.OptionsPassthrough {
+			writeAPIGatewayResponse(w, s.handleOptionsResponse(events.APIGatewayProxyRequest{Path: r.URL.Path, Headers: headers}), s.logger)
+			return
+		}
+	}
+
+	root, ok := s.trees[method]
+	var node *radixNode
+	var params map[string]string
+	if ok {
+		node, params, ok = root.lookup(segments)
+	}
+	if !ok {
+		if allowed := s.methodsForPath(segments); allowed != "" {
+			w.Header().Set("Allow", allowed)
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	query := make(map[string]string)
+	for key := range r.URL.Query() {
+		query[key] = r.URL.Query().Get(key)
+	}
+
+	req := events.APIGatewayProxyRequest{
+		HTTPMethod:            r.Method,
+		Path:                  r.URL.Path,
+		Body:                  string(body),
+		Headers:               headers,
+		QueryStringParameters: query,
+		PathParameters:        params,
+	}
+
+	requestID := requestIDFromHeaders(headers)
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	ctx := withRequestID(r.Context(), requestID)
+
+	start := time.Now()
+	resp, err := s.withMiddleware(node.handler)(ctx, req)
+	s.logRequest(ctx, method, node.routePath, params, string(body), resp.StatusCode, time.Since(start), err)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	writeAPIGatewayResponse(w, resp, s.logger)
+}
+
+// writeAPIGatewayResponse copies an events.APIGatewayProxyResponse onto an
+// http.ResponseWriter, base64-decoding the body when IsBase64Encoded is set.
+func writeAPIGatewayResponse(w http.ResponseWriter, resp events.APIGatewayProxyResponse, logger Logger) {
+	for key, value := range resp.Headers {
+		w.Header().Set(key, value)
+	}
+
+	body := []byte(resp.Body)
+	if resp.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(resp.Body)
+		if err != nil {
+			logger.Error("failed to base64-decode response body", "error", err.Error())
+		} else {
+			body = decoded
+		}
+	}
+
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestHTTPHandlerServesRoute(t *testing.T) {
+	s := newTestServer()
+	if err := s.MountEndpoint(GET, "/users/:id", func(_ context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"X-User-Id": req.PathParameters["id"]},
+			Body:       "hello " + req.PathParameters["id"],
+		}, nil
+	}); err != nil {
+		t.Fatalf("unexpected error mounting /users/:id: %v", err)
+	}
+
+	ts := httptest.NewServer(s.HTTPHandler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/users/42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("X-User-Id"); got != "42" {
+		t.Errorf("X-User-Id header = %q, want %q", got, "42")
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "hello 42" {
+		t.Errorf("body = %q, want %q", string(body), "hello 42")
+	}
+}
+
+func TestHTTPHandlerDecodesBase64Response(t *testing.T) {
+	s := newTestServer()
+	if err := s.MountEndpoint(GET, "/binary", func(_ context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{
+			StatusCode:      http.StatusOK,
+			Body:            base64.StdEncoding.EncodeToString([]byte("raw bytes")),
+			IsBase64Encoded: true,
+		}, nil
+	}); err != nil {
+		t.Fatalf("unexpected error mounting /binary: %v", err)
+	}
+
+	ts := httptest.NewServer(s.HTTPHandler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/binary")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "raw bytes" {
+		t.Errorf("body = %q, want %q", string(body), "raw bytes")
+	}
+}
+
+func TestHTTPHandlerMethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	if err := s.MountEndpoint(GET, "/users/:id", noopHandler); err != nil {
+		t.Fatalf("unexpected error mounting /users/:id: %v", err)
+	}
+
+	ts := httptest.NewServer(s.HTTPHandler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/users/42", "application/json", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+	if got := resp.Header.Get("Allow"); got != "GET" {
+		t.Errorf("Allow header = %q, want %q", got, "GET")
+	}
+}
+
+func TestHTTPHandlerNotFound(t *testing.T) {
+	s := newTestServer()
+	ts := httptest.NewServer(s.HTTPHandler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/nope")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Logger is the structured logging surface Server uses for its own request
+// and lifecycle logs. Its method set matches *log/slog.Logger, so a
+// *slog.Logger can be passed directly to WithLogger.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// ServerOption configures a Server at construction time, via
+// NewServerWithOptions.
+type ServerOption func(*Server)
+
+// WithLogger replaces the server's default *slog.Logger (slog.Default())
+// with logger, used for every request and lifecycle log Server emits.
+func WithLogger(logger Logger) ServerOption {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithBodyLogging opts into including the request body in each request's
+// structured log record, passed through redact first. Request bodies are
+// never logged unless this is set, since they routinely carry sensitive
+// data (credentials, PII) that shouldn't end up in CloudWatch Logs.
+func WithBodyLogging(redact func(body string) string) ServerOption {
+	return func(s *Server) {
+		s.bodyRedactor = redact
+	}
+}
+
+// requestIDFromHeaders extracts a correlation ID from the headers a client
+// sent, preferring the X-Ray/API-Gateway trace header over a generic
+// request-ID header.
+func requestIDFromHeaders(headers map[string]string) string {
+	if id := headerValue(headers, "X-Amzn-Trace-Id"); id != "" {
+		return id
+	}
+	return headerValue(headers, "X-Request-Id")
+}
+
+// logRequest emits one structured record for a completed request: method,
+// route template, matched path params, status, latency, request ID, and
+// error (if any). The request body is included only if WithBodyLogging was
+// used to opt in.
+func (s *Server) logRequest(ctx context.Context, method Method, route string, params map[string]string, body string, status int, latency time.Duration, err error) {
+	requestID, _ := RequestIDFromContext(ctx)
+	args := []any{
+		"method", string(method),
+		"route", route,
+		"params", params,
+		"status", status,
+		"latency_ms", latency.Milliseconds(),
+		"request_id", requestID,
+	}
+	if s.bodyRedactor != nil {
+		args = append(args, "body", s.bodyRedactor(body))
+	}
+	if err != nil {
+		args = append(args, "error", err.Error())
+		s.logger.Error("request completed", args...)
+		return
+	}
+	s.logger.Info("request completed", args...)
+}
+
+// defaultLogger returns the slog default logger, satisfying Logger.
+func defaultLogger() Logger {
+	return slog.Default()
+}
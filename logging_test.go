@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+type capturingLogger struct {
+	records []string
+}
+
+func (l *capturingLogger) Debug(msg string, args ...any) {
+	l.records = append(l.records, l.format("DEBUG", msg, args))
+}
+func (l *capturingLogger) Info(msg string, args ...any) {
+	l.records = append(l.records, l.format("INFO", msg, args))
+}
+func (l *capturingLogger) Warn(msg string, args ...any) {
+	l.records = append(l.records, l.format("WARN", msg, args))
+}
+func (l *capturingLogger) Error(msg string, args ...any) {
+	l.records = append(l.records, l.format("ERROR", msg, args))
+}
+
+func (l *capturingLogger) format(level, msg string, args []any) string {
+	var b strings.Builder
+	b.WriteString(level)
+	b.WriteString(" ")
+	b.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		b.WriteString(" ")
+		if s, ok := args[i].(string); ok {
+			b.WriteString(s)
+		}
+		b.WriteString("=")
+		b.WriteString(toString(args[i+1]))
+	}
+	return b.String()
+}
+
+func toString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if e, ok := v.(error); ok {
+		return e.Error()
+	}
+	return ""
+}
+
+func TestRequestIDFromHeadersPrefersTraceID(t *testing.T) {
+	headers := map[string]string{
+		"X-Amzn-Trace-Id": "trace-1",
+		"X-Request-Id":    "req-1",
+	}
+	if got := requestIDFromHeaders(headers); got != "trace-1" {
+		t.Errorf("requestIDFromHeaders = %q, want %q", got, "trace-1")
+	}
+}
+
+func TestRequestIDFromHeadersFallsBackToRequestID(t *testing.T) {
+	headers := map[string]string{"X-Request-Id": "req-1"}
+	if got := requestIDFromHeaders(headers); got != "req-1" {
+		t.Errorf("requestIDFromHeaders = %q, want %q", got, "req-1")
+	}
+}
+
+func TestLogRequestOmitsBodyByDefault(t *testing.T) {
+	logger := &capturingLogger{}
+	s := newTestServer()
+	s.logger = logger
+
+	s.logRequest(context.Background(), GET, "/users/:id", map[string]string{"id": "42"}, "super-secret-body", 200, 5*time.Millisecond, nil)
+
+	if len(logger.records) != 1 {
+		t.Fatalf("expected exactly one log record, got %d", len(logger.records))
+	}
+	if strings.Contains(logger.records[0], "super-secret-body") {
+		t.Errorf("request body leaked into log record without WithBodyLogging: %q", logger.records[0])
+	}
+}
+
+func TestLogRequestIncludesRedactedBodyWhenOptedIn(t *testing.T) {
+	logger := &capturingLogger{}
+	s := newTestServer()
+	s.logger = logger
+	s.bodyRedactor = func(body string) string { return "[redacted]" }
+
+	s.logRequest(context.Background(), POST, "/users", nil, "super-secret-body", 201, time.Millisecond, nil)
+
+	if len(logger.records) != 1 || !strings.Contains(logger.records[0], "[redacted]") {
+		t.Fatalf("expected redacted body in log record, got %v", logger.records)
+	}
+	if strings.Contains(logger.records[0], "super-secret-body") {
+		t.Errorf("raw request body leaked into log record: %q", logger.records[0])
+	}
+}
+
+func TestLogRequestLogsErrorsAtErrorLevel(t *testing.T) {
+	logger := &capturingLogger{}
+	s := newTestServer()
+	s.logger = logger
+
+	s.logRequest(context.Background(), GET, "/boom", nil, "", 500, time.Millisecond, context.DeadlineExceeded)
+
+	if len(logger.records) != 1 || !strings.HasPrefix(logger.records[0], "ERROR") {
+		t.Fatalf("expected an ERROR-level record, got %v", logger.records)
+	}
+}
+
+func TestDefaultLoggerSatisfiesLogger(t *testing.T) {
+	var _ Logger = defaultLogger()
+	var _ Logger = slog.Default()
+}
@@ -2,9 +2,9 @@ package server
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,118 +36,211 @@ type Route struct {
 	Method  Method
 	Path    string
 	Handler HandlerFunction
+	CORS    *CORSConfig // per-route override; nil means the server-wide policy applies
 }
 
 // Server encapsulates both Lambda and local server behavior
 type Server struct {
 	router         *gin.Engine
 	lambda         bool
-	allowedMethods map[Method]bool // Tracks allowed methods for CORS
-	routes         []Route         // Tracks all mounted routes
+	allowedMethods map[Method]bool       // Tracks allowed methods for CORS
+	routes         []Route               // Tracks all mounted routes, for local (Gin) dispatch
+	trees          map[Method]*radixNode // Per-method path tries, for Lambda dispatch
+	corsConfig     CORSConfig            // Server-wide CORS policy, see UseCORS
+	middleware     []Middleware          // Chain applied to every request, see Use
+	logger         Logger                // Structured request/lifecycle logger, see WithLogger
+	bodyRedactor   func(string) string   // Set via WithBodyLogging; nil means bodies are never logged
 }
 
-// NewServer creates a new server instance
+// NewServer creates a new server instance, logging via slog.Default().
 func NewServer() *Server {
+	return NewServerWithOptions()
+}
+
+// NewServerWithOptions creates a new server instance, applying opts (such as
+// WithLogger or WithBodyLogging) over the defaults.
+func NewServerWithOptions(opts ...ServerOption) *Server {
 	server := &Server{
 		router:         gin.Default(),
 		lambda:         os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "",
 		allowedMethods: make(map[Method]bool),
 		routes:         []Route{},
+		trees:          make(map[Method]*radixNode),
+		corsConfig:     DefaultCORSConfig(),
+		logger:         defaultLogger(),
+	}
+	for _, opt := range opts {
+		opt(server)
 	}
 	return server
 }
 
-// MountEndpoint adds an endpoint with a specified handler
-func (s *Server) MountEndpoint(method Method, path string, handler HandlerFunction) {
+// MountEndpoint adds an endpoint with a specified handler. It returns a
+// *RouteConflictError if path conflicts with a route already registered for
+// method (e.g. mounting both `/users/:id` and `/users/me`), so callers must
+// check the error rather than discovering the collision at request time.
+// Pass WithCORS to override the server-wide CORS policy for this route.
+func (s *Server) MountEndpoint(method Method, path string, handler HandlerFunction, opts ...EndpointOption) error {
 	// Convert {param} to :param for Gin compatibility
 	convertedPath := strings.ReplaceAll(path, "{", ":")
 	convertedPath = strings.ReplaceAll(convertedPath, "}", "")
 
-	log.Printf("[Server] Mounting endpoint: %s %s", method, convertedPath)
+	var o endpointOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	root, ok := s.trees[method]
+	if !ok {
+		root = newRadixNode()
+		s.trees[method] = root
+	}
+	if err := root.insert(method, convertedPath, splitSegments(convertedPath), handler, o.cors); err != nil {
+		return err
+	}
+
+	s.logger.Info("mounting endpoint", "method", string(method), "route", convertedPath)
 	s.allowedMethods[method] = true // Track allowed methods dynamically
-	s.routes = append(s.routes, Route{Method: method, Path: convertedPath, Handler: handler})
+	s.routes = append(s.routes, Route{Method: method, Path: convertedPath, Handler: handler, CORS: o.cors})
+	return nil
 }
 
 // Serve starts the server
 func (s *Server) Serve(port string) {
 	if s.lambda {
-		// Lambda mode: Start Lambda with a single handler
-		log.Println("[Server] Running in Lambda mode")
-		lambda.Start(s.handleLambdaRequest)
+		// Lambda mode: Start Lambda with a single handler that dispatches on
+		// payload shape, so the same Server can sit behind a REST API
+		// (v1), an HTTP API (v2), or an ALB target group.
+		s.logger.Info("starting server", "mode", "lambda")
+		lambda.Start(s.dispatch)
 	} else {
 		s.handleServerStart(port)
 	}
 }
 
-// handleLambdaRequest handles all Lambda requests dynamically, extracting path parameters
-func (s *Server) handleLambdaRequest(req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	log.Printf("[Lambda] Received request: Method=%s Path=%s", req.HTTPMethod, req.Path)
-	ctx := context.Background()
-
-	// Handle OPTIONS requests for CORS
-	if req.HTTPMethod == "OPTIONS" {
-		return s.handleOptionsResponse(), nil
+// handleLambdaRequest handles all Lambda requests dynamically, extracting path parameters.
+// ctx is the real context from lambda.Start, carrying the invocation's
+// remaining-time deadline and (via lambdacontext.FromContext) the Lambda
+// invocation context.
+func (s *Server) handleLambdaRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	start := time.Now()
+
+	ctx = withAPIGatewayRequestContext(ctx, req.RequestContext)
+	requestID := req.RequestContext.RequestID
+	if requestID == "" {
+		if lc, ok := LambdaContextFromContext(ctx); ok {
+			requestID = lc.AwsRequestID
+		}
+	}
+	if requestID == "" {
+		requestID = requestIDFromHeaders(req.Headers)
 	}
+	ctx = withRequestID(ctx, requestID)
 
-	// Match routes dynamically
-	for _, route := range s.routes {
-		if matchPath(req.Path, route.Path) && strings.EqualFold(req.HTTPMethod, string(route.Method)) {
-			log.Printf("[Lambda] Handling request for: %s %s", route.Method, route.Path)
+	segments := splitSegments(req.Path)
+	method := Method(strings.ToUpper(req.HTTPMethod))
+
+	// Handle OPTIONS requests for CORS, unless the matching route's policy
+	// asks to pass preflight requests through to its own OPTIONS handler.
+	if method == OPTIONS {
+		_, cors := s.routeMatchForPath(segments, preferredCORSMethod(req.Headers))
+		if cors == nil {
+			cors = &s.corsConfig
+		}
+		if !cors.OptionsPassthrough {
+			resp := s.handleOptionsResponse(req)
+			s.logRequest(ctx, method, req.Path, nil, req.Body, resp.StatusCode, time.Since(start), nil)
+			return resp, nil
+		}
+	}
 
-			// Extract path parameters from request
-			pathParams := extractPathParams(req.Path, route.Path)
-			req.PathParameters = pathParams
+	if root, ok := s.trees[method]; ok {
+		if node, params, ok := root.lookup(segments); ok {
+			req.PathParameters = params
+			resp, err := s.withMiddleware(node.handler)(ctx, req)
+			s.logRequest(ctx, method, node.routePath, params, req.Body, resp.StatusCode, time.Since(start), err)
+			return resp, err
+		}
+	}
 
-			return route.Handler(ctx, req)
+	// The path matches a mounted route, just not for this method: reply 405
+	// with the methods that are actually registered, instead of a bare 404.
+	if allowed := s.methodsForPath(segments); len(allowed) > 0 {
+		resp := events.APIGatewayProxyResponse{
+			StatusCode: http.StatusMethodNotAllowed,
+			Headers:    map[string]string{"Allow": allowed},
+			Body:       "Method Not Allowed",
 		}
+		s.logRequest(ctx, method, req.Path, nil, req.Body, resp.StatusCode, time.Since(start), nil)
+		return resp, nil
 	}
 
 	// No matching route
-	log.Printf("[Lambda] No handler found for: Method=%s Path=%s", req.HTTPMethod, req.Path)
-	return events.APIGatewayProxyResponse{StatusCode: http.StatusNotFound, Body: "Not Found"}, nil
+	resp := events.APIGatewayProxyResponse{StatusCode: http.StatusNotFound, Body: "Not Found"}
+	s.logRequest(ctx, method, req.Path, nil, req.Body, resp.StatusCode, time.Since(start), nil)
+	return resp, nil
 }
 
-// Matches a request path against a registered route, handling path parameters
-func matchPath(requestPath, routePath string) bool {
-	requestParts := strings.Split(strings.Trim(requestPath, "/"), "/")
-	routeParts := strings.Split(strings.Trim(routePath, "/"), "/")
-
-	if len(requestParts) != len(routeParts) {
-		return false
-	}
+// methodsForPath returns the comma-joined, sorted set of methods that have a
+// route mounted for segments, across all method tries. Used to populate the
+// Allow header on a 405 response.
+func (s *Server) methodsForPath(segments []string) string {
+	methods, _ := s.routeMatchForPath(segments, "")
+	return strings.Join(methods, ", ")
+}
 
-	for i := range requestParts {
-		if routeParts[i] == "" || routeParts[i] == requestParts[i] || strings.HasPrefix(routeParts[i], ":") {
-			continue
+// routeMatchForPath looks up segments across every method trie, returning the
+// sorted list of methods mounted for that path and the CORS override (if
+// any) that applies to a preflight for that path. A nil *CORSConfig means
+// the chosen route set none, so the server-wide policy applies.
+//
+// preferredMethod, when one of the matching methods, names the policy to use
+// (the method the preflight's Access-Control-Request-Method header says the
+// real request will use). Otherwise the lexicographically first matching
+// method that carries an override wins, so the choice is deterministic
+// instead of depending on s.trees' randomized map iteration order.
+func (s *Server) routeMatchForPath(segments []string, preferredMethod Method) ([]string, *CORSConfig) {
+	var methods []string
+	nodes := make(map[Method]*radixNode, len(s.trees))
+	for method, root := range s.trees {
+		if node, _, ok := root.lookup(segments); ok {
+			methods = append(methods, string(method))
+			nodes[method] = node
 		}
-		return false
 	}
-	return true
-}
-
-// Extracts path parameters from a request based on the route definition
-func extractPathParams(requestPath, routePath string) map[string]string {
-	requestParts := strings.Split(strings.Trim(requestPath, "/"), "/")
-	routeParts := strings.Split(strings.Trim(routePath, "/"), "/")
+	slices.Sort(methods)
 
-	params := make(map[string]string)
-	for i := range routeParts {
-		if strings.HasPrefix(routeParts[i], ":") {
-			paramName := strings.TrimPrefix(routeParts[i], ":")
-			params[paramName] = requestParts[i]
+	if node, ok := nodes[preferredMethod]; ok {
+		return methods, node.cors
+	}
+	for _, m := range methods {
+		if node := nodes[Method(m)]; node.cors != nil {
+			return methods, node.cors
 		}
 	}
-	return params
+	return methods, nil
 }
 
 // Local server startup logic
 func (s *Server) handleServerStart(port string) {
-	log.Println("[Server] Running in local server mode")
-	s.router.Use(s.setupCORS())
+	s.logger.Info("starting server", "mode", "local")
 
 	for _, route := range s.routes {
-		s.router.Handle(string(route.Method), route.Path, func(c *gin.Context) {
-			ctx := context.Background()
+		s.router.Handle(string(route.Method), route.Path, s.corsMiddlewareFor(route), func(c *gin.Context) {
+			start := time.Now()
+
+			// Convert request headers to a map
+			headers := make(map[string]string)
+			for key := range c.Request.Header {
+				headers[key] = c.Request.Header.Get(key)
+			}
+
+			requestID := requestIDFromHeaders(headers)
+			if requestID == "" {
+				requestID = newRequestID()
+			}
+			ctx := withRequestID(c.Request.Context(), requestID)
+
 			body, _ := c.GetRawData()
 			query := c.Request.URL.Query()
 
@@ -157,19 +250,14 @@ func (s *Server) handleServerStart(port string) {
 				queryParams[key] = query.Get(key)
 			}
 
-			// Convert request headers to a map
-			headers := make(map[string]string)
-			for key := range c.Request.Header {
-				headers[key] = c.Request.Header.Get(key)
-			}
-
 			// Extract path parameters from Gin context
 			pathParams := make(map[string]string)
 			for _, param := range c.Params {
 				pathParams[param.Key] = param.Value
 			}
 
-			statusCode, response := serveHTTPHandler(ctx, route.Handler, string(body), queryParams, headers, pathParams)
+			statusCode, response, err := serveHTTPHandler(ctx, s.withMiddleware(route.Handler), string(body), queryParams, headers, pathParams)
+			s.logRequest(ctx, route.Method, route.Path, pathParams, string(body), statusCode, time.Since(start), err)
 			c.String(statusCode, response)
 		})
 	}
@@ -177,26 +265,67 @@ func (s *Server) handleServerStart(port string) {
 	if port == "" {
 		port = "8080"
 	}
-	log.Printf("[Server] Server running on port %s", port)
+	s.logger.Info("server running", "port", port)
 	s.router.Run(":" + port)
 }
 
-// Generates a response for OPTIONS (CORS preflight requests)
-func (s *Server) handleOptionsResponse() events.APIGatewayProxyResponse {
-	allowedMethods := s.getAllowedMethods()
+// Generates a response for OPTIONS (CORS preflight requests), honoring the
+// CORS policy of whichever route matches the preflight's path (falling back
+// to the server-wide policy), rather than a single hard-coded wildcard.
+func (s *Server) handleOptionsResponse(req events.APIGatewayProxyRequest) events.APIGatewayProxyResponse {
+	segments := splitSegments(req.Path)
+	methods, cors := s.routeMatchForPath(segments, preferredCORSMethod(req.Headers))
+	if cors == nil {
+		cors = &s.corsConfig
+	}
+	if len(methods) == 0 {
+		// Nothing registered for this exact path; fall back to every method
+		// the server knows about so unrecognized paths still get a sane
+		// preflight response instead of none at all.
+		methods = s.getAllowedMethods()
+	} else {
+		methods = append(methods, string(OPTIONS))
+		slices.Sort(methods)
+	}
+
 	headers := map[string]string{
-		"Access-Control-Allow-Origin":  "*",
-		"Access-Control-Allow-Methods": strings.Join(allowedMethods, ", "),
-		"Access-Control-Allow-Headers": "Content-Type, Authorization, Accept-Language",
+		"Access-Control-Allow-Methods": strings.Join(methods, ", "),
+		"Access-Control-Allow-Headers": strings.Join(cors.AllowedHeaders, ", "),
+	}
+	if len(cors.ExposedHeaders) > 0 {
+		headers["Access-Control-Expose-Headers"] = strings.Join(cors.ExposedHeaders, ", ")
+	}
+	if cors.MaxAge > 0 {
+		headers["Access-Control-Max-Age"] = strconv.Itoa(int(cors.MaxAge.Seconds()))
+	}
+
+	if origin := headerValue(req.Headers, "Origin"); matchOrigin(origin, cors.AllowedOrigins) {
+		headers["Access-Control-Allow-Origin"] = origin
+		headers["Vary"] = "Origin"
+		if cors.AllowCredentials {
+			headers["Access-Control-Allow-Credentials"] = "true"
+		}
 	}
-	log.Printf("[Lambda] Responding to OPTIONS with headers: %+v", headers)
+
+	s.logger.Debug("responding to preflight", "route", req.Path, "headers", headers)
 	return events.APIGatewayProxyResponse{
 		StatusCode: http.StatusOK,
 		Headers:    headers,
 	}
 }
+
+// headerValue looks up key in headers case-insensitively, since header
+// casing on the wire (and as preserved by API Gateway) isn't guaranteed.
+func headerValue(headers map[string]string, key string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
 // Serve HTTP request handler with path parameters
-func serveHTTPHandler(ctx context.Context, handler HandlerFunction, body string, query map[string]string, headers map[string]string, pathParams map[string]string) (int, string) {
+func serveHTTPHandler(ctx context.Context, handler HandlerFunction, body string, query map[string]string, headers map[string]string, pathParams map[string]string) (int, string, error) {
 	req := events.APIGatewayProxyRequest{
 		Body:                  body,
 		QueryStringParameters: query,
@@ -206,22 +335,27 @@ func serveHTTPHandler(ctx context.Context, handler HandlerFunction, body string,
 
 	response, err := handler(ctx, req)
 	if err != nil {
-		log.Printf("Handler error: %v", err)
-		return http.StatusInternalServerError, "Internal Server Error"
+		return http.StatusInternalServerError, "Internal Server Error", err
 	}
 
-	return response.StatusCode, response.Body
+	return response.StatusCode, response.Body, nil
 }
 
-// setupCORS dynamically generates the CORS configuration
-func (s *Server) setupCORS() gin.HandlerFunc {
+// corsMiddlewareFor builds the CORS middleware for a single route, using its
+// per-route override if MountEndpoint was given one, or the server-wide
+// policy otherwise.
+func (s *Server) corsMiddlewareFor(route Route) gin.HandlerFunc {
+	cfg := s.corsConfig
+	if route.CORS != nil {
+		cfg = *route.CORS
+	}
 	return cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     s.getAllowedMethods(),
-		AllowHeaders:     []string{"Content-Type", "Authorization", "Accept-Language"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+		AllowOriginFunc:  func(origin string) bool { return matchOrigin(origin, cfg.AllowedOrigins) },
+		AllowMethods:     []string{string(route.Method), string(OPTIONS)},
+		AllowHeaders:     cfg.AllowedHeaders,
+		ExposeHeaders:    cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
 	})
 }
 
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func newTestServer() *Server {
+	return &Server{
+		allowedMethods: make(map[Method]bool),
+		routes:         []Route{},
+		trees:          make(map[Method]*radixNode),
+		corsConfig:     DefaultCORSConfig(),
+		logger:         defaultLogger(),
+	}
+}
+
+func TestMountEndpointRejectsConflictingRoute(t *testing.T) {
+	s := newTestServer()
+
+	if err := s.MountEndpoint(GET, "/users/:id", noopHandler); err != nil {
+		t.Fatalf("unexpected error mounting /users/:id: %v", err)
+	}
+
+	err := s.MountEndpoint(GET, "/users/me", noopHandler)
+	if err == nil {
+		t.Fatal("expected MountEndpoint to reject /users/me alongside /users/:id")
+	}
+	if _, ok := err.(*RouteConflictError); !ok {
+		t.Fatalf("expected *RouteConflictError, got %T", err)
+	}
+}
+
+func TestMountEndpointAllowsSamePathDifferentMethod(t *testing.T) {
+	s := newTestServer()
+
+	if err := s.MountEndpoint(GET, "/users/:id", noopHandler); err != nil {
+		t.Fatalf("unexpected error mounting GET /users/:id: %v", err)
+	}
+	if err := s.MountEndpoint(POST, "/users/:id", noopHandler); err != nil {
+		t.Fatalf("unexpected error mounting POST /users/:id: %v", err)
+	}
+}
+
+func TestHandleLambdaRequestMethodNotAllowed(t *testing.T) {
+	s := newTestServer()
+	if err := s.MountEndpoint(GET, "/users/:id", noopHandler); err != nil {
+		t.Fatalf("unexpected error mounting /users/:id: %v", err)
+	}
+	if err := s.MountEndpoint(POST, "/users/:id", noopHandler); err != nil {
+		t.Fatalf("unexpected error mounting /users/:id: %v", err)
+	}
+
+	resp, err := s.handleLambdaRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "DELETE",
+		Path:       "/users/42",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 405 {
+		t.Fatalf("StatusCode = %d, want 405", resp.StatusCode)
+	}
+	if allow := resp.Headers["Allow"]; allow != "GET, POST" {
+		t.Errorf("Allow header = %q, want %q", allow, "GET, POST")
+	}
+}
+
+func TestHandleLambdaRequestNotFound(t *testing.T) {
+	s := newTestServer()
+	if err := s.MountEndpoint(GET, "/users/:id", noopHandler); err != nil {
+		t.Fatalf("unexpected error mounting /users/:id: %v", err)
+	}
+
+	resp, err := s.handleLambdaRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "GET",
+		Path:       "/accounts/42",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 404 {
+		t.Fatalf("StatusCode = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestHandleLambdaRequestTrailingSlash(t *testing.T) {
+	s := newTestServer()
+	if err := s.MountEndpoint(GET, "/users/:id", noopHandler); err != nil {
+		t.Fatalf("unexpected error mounting /users/:id: %v", err)
+	}
+
+	resp, err := s.handleLambdaRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "GET",
+		Path:       "/users/42/",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("StatusCode = %d, want 200", resp.StatusCode)
+	}
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// Middleware wraps a HandlerFunction with cross-cutting behavior (auth,
+// logging, tracing, panic recovery, per-route timeouts, ...). It runs in
+// both Lambda and local server mode, since both dispatch paths apply the
+// same chain before invoking the matched route's handler.
+type Middleware func(HandlerFunction) HandlerFunction
+
+// Use appends middleware to the chain applied to every request, in the
+// order given: the first middleware passed is the outermost, so it sees the
+// request before (and the response after) the ones that follow it.
+func (s *Server) Use(mw ...Middleware) {
+	s.middleware = append(s.middleware, mw...)
+}
+
+// withMiddleware wraps handler with the server's middleware chain.
+func (s *Server) withMiddleware(handler HandlerFunction) HandlerFunction {
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		handler = s.middleware[i](handler)
+	}
+	return handler
+}
+
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	apiGatewayRequestContextKey
+)
+
+// withRequestID attaches a request ID to ctx, retrievable via
+// RequestIDFromContext.
+func withRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID attached by the dispatch path
+// handling the current request: the API Gateway request ID (falling back to
+// the Lambda context's AwsRequestID, then to the X-Amzn-Trace-Id or
+// X-Request-Id header) in Lambda mode, or in local server and HTTPHandler
+// mode that header value, with a synthesized ID when neither header is sent.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDContextKey).(string)
+	return id, ok
+}
+
+// withAPIGatewayRequestContext attaches req's API Gateway RequestContext to
+// ctx, retrievable via APIGatewayRequestContextFromContext.
+func withAPIGatewayRequestContext(ctx context.Context, rc events.APIGatewayProxyRequestContext) context.Context {
+	return context.WithValue(ctx, apiGatewayRequestContextKey, rc)
+}
+
+// APIGatewayRequestContextFromContext returns the API Gateway proxy
+// RequestContext for the current request. It is only populated in Lambda
+// mode; local server and HTTPHandler requests have no API Gateway in front
+// of them, so the second return value is false.
+func APIGatewayRequestContextFromContext(ctx context.Context) (events.APIGatewayProxyRequestContext, bool) {
+	rc, ok := ctx.Value(apiGatewayRequestContextKey).(events.APIGatewayProxyRequestContext)
+	return rc, ok
+}
+
+// LambdaContextFromContext returns the AWS Lambda invocation context (ARN,
+// deadline, cognito/client identity) for the current request. It is only
+// populated in Lambda mode.
+func LambdaContextFromContext(ctx context.Context) (*lambdacontext.LambdaContext, bool) {
+	return lambdacontext.FromContext(ctx)
+}
+
+// newRequestID generates a request ID for dispatch paths that aren't backed
+// by a real Lambda invocation (local server mode, HTTPHandler).
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
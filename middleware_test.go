@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// TestUseOrdering confirms the documented contract: the first middleware
+// passed to Use is outermost, so it sees the request before (and the
+// response after) the ones that follow it.
+func TestUseOrdering(t *testing.T) {
+	var order []string
+
+	trace := func(name string) Middleware {
+		return func(next HandlerFunction) HandlerFunction {
+			return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				order = append(order, name+":before")
+				resp, err := next(ctx, req)
+				order = append(order, name+":after")
+				return resp, err
+			}
+		}
+	}
+
+	s := newTestServer()
+	s.Use(trace("outer"), trace("inner"))
+
+	handler := s.withMiddleware(func(_ context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		order = append(order, "handler")
+		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+	})
+
+	if _, err := handler(context.Background(), events.APIGatewayProxyRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"outer:before", "inner:before", "handler", "inner:after", "outer:after"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestWithMiddlewareNoopWhenEmpty(t *testing.T) {
+	s := newTestServer()
+
+	called := false
+	handler := s.withMiddleware(func(_ context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		called = true
+		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+	})
+
+	if _, err := handler(context.Background(), events.APIGatewayProxyRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called {
+		t.Error("expected the handler to run when no middleware is registered")
+	}
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	if _, ok := RequestIDFromContext(context.Background()); ok {
+		t.Error("expected no request ID on a bare context")
+	}
+
+	ctx := withRequestID(context.Background(), "req-123")
+	id, ok := RequestIDFromContext(ctx)
+	if !ok || id != "req-123" {
+		t.Errorf("RequestIDFromContext = (%q, %v), want (%q, true)", id, ok, "req-123")
+	}
+}
+
+func TestAPIGatewayRequestContextFromContext(t *testing.T) {
+	if _, ok := APIGatewayRequestContextFromContext(context.Background()); ok {
+		t.Error("expected no API Gateway request context on a bare context")
+	}
+
+	rc := events.APIGatewayProxyRequestContext{RequestID: "req-123"}
+	ctx := withAPIGatewayRequestContext(context.Background(), rc)
+	got, ok := APIGatewayRequestContextFromContext(ctx)
+	if !ok || got.RequestID != "req-123" {
+		t.Errorf("APIGatewayRequestContextFromContext = (%+v, %v), want RequestID=req-123", got, ok)
+	}
+}
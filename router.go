@@ -0,0 +1,147 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// radixNode is a node in a per-method path trie. At any given position a
+// node either fans out into literal segments or a single named parameter,
+// never both — that constraint is what lets insert() detect conflicts like
+// `/users/:id` vs `/users/me` at mount time instead of at request time.
+type radixNode struct {
+	static    map[string]*radixNode
+	param     *radixNode
+	paramName string
+	handler   HandlerFunction
+	routePath string      // original (converted) route path, for conflict messages
+	cors      *CORSConfig // per-route CORS override; nil falls back to Server.corsConfig
+}
+
+func newRadixNode() *radixNode {
+	return &radixNode{static: make(map[string]*radixNode)}
+}
+
+// RouteConflictError reports that a route registration collides with a
+// route already mounted for the same method.
+type RouteConflictError struct {
+	Method       Method
+	NewPath      string
+	ExistingPath string
+}
+
+func (e *RouteConflictError) Error() string {
+	return fmt.Sprintf("server: route %s %q conflicts with already-registered %s %q", e.Method, e.NewPath, e.Method, e.ExistingPath)
+}
+
+// splitSegments splits a route or request path into its non-empty segments.
+func splitSegments(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "/")
+}
+
+// insert adds routePath (already split into segments) to the trie rooted at
+// n, returning a *RouteConflictError if it collides with an existing
+// registration reachable from n.
+func (n *radixNode) insert(method Method, routePath string, segments []string, handler HandlerFunction, cors *CORSConfig) error {
+	cur := n
+	for _, seg := range segments {
+		if strings.HasPrefix(seg, ":") {
+			name := strings.TrimPrefix(seg, ":")
+			if existing := cur.anyStaticPath(); existing != "" {
+				return &RouteConflictError{Method: method, NewPath: routePath, ExistingPath: existing}
+			}
+			if cur.param == nil {
+				cur.param = newRadixNode()
+				cur.param.paramName = name
+			} else if cur.param.paramName != name {
+				return &RouteConflictError{Method: method, NewPath: routePath, ExistingPath: cur.param.anyDescendantPath()}
+			}
+			cur = cur.param
+			continue
+		}
+
+		if cur.param != nil {
+			return &RouteConflictError{Method: method, NewPath: routePath, ExistingPath: cur.param.anyDescendantPath()}
+		}
+		next, ok := cur.static[seg]
+		if !ok {
+			next = newRadixNode()
+			cur.static[seg] = next
+		}
+		cur = next
+	}
+
+	if cur.handler != nil {
+		return &RouteConflictError{Method: method, NewPath: routePath, ExistingPath: cur.routePath}
+	}
+	cur.handler = handler
+	cur.routePath = routePath
+	cur.cors = cors
+	return nil
+}
+
+// anyStaticPath reports the route path of some route reachable through a
+// static child of n, or "" if n has no static children registered yet. It
+// only needs to produce *an* example for the conflict error, not the exact
+// colliding route.
+func (n *radixNode) anyStaticPath() string {
+	for _, child := range n.static {
+		if p := child.anyDescendantPath(); p != "" {
+			return p
+		}
+	}
+	return ""
+}
+
+// anyDescendantPath reports the route path of some route reachable from n,
+// including n itself, or "" if none is registered yet. insert uses this (via
+// a param node) the same way anyStaticPath uses it for static children, so a
+// conflict against an only-partially-registered branch still names a real
+// route instead of the empty string.
+func (n *radixNode) anyDescendantPath() string {
+	if n.routePath != "" {
+		return n.routePath
+	}
+	for _, child := range n.static {
+		if p := child.anyDescendantPath(); p != "" {
+			return p
+		}
+	}
+	if n.param != nil {
+		if p := n.param.anyDescendantPath(); p != "" {
+			return p
+		}
+	}
+	return ""
+}
+
+// lookup walks segments through the trie in a single traversal, returning
+// the node with the mounted handler (if any) and the path parameters
+// collected along the way.
+func (n *radixNode) lookup(segments []string) (*radixNode, map[string]string, bool) {
+	cur := n
+	var params map[string]string
+	for _, seg := range segments {
+		if next, ok := cur.static[seg]; ok {
+			cur = next
+			continue
+		}
+		if cur.param != nil {
+			if params == nil {
+				params = make(map[string]string, len(segments))
+			}
+			params[cur.param.paramName] = seg
+			cur = cur.param
+			continue
+		}
+		return nil, nil, false
+	}
+	if cur.handler == nil {
+		return nil, nil, false
+	}
+	return cur, params, true
+}
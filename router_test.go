@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func noopHandler(_ context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+}
+
+func TestRadixNodeInsertConflictWildcardOverlap(t *testing.T) {
+	root := newRadixNode()
+
+	if err := root.insert(GET, "/users/:id", splitSegments("/users/:id"), noopHandler, nil); err != nil {
+		t.Fatalf("unexpected error registering /users/:id: %v", err)
+	}
+
+	err := root.insert(GET, "/users/me", splitSegments("/users/me"), noopHandler, nil)
+	if err == nil {
+		t.Fatal("expected a conflict registering /users/me alongside /users/:id")
+	}
+	conflict, ok := err.(*RouteConflictError)
+	if !ok {
+		t.Fatalf("expected *RouteConflictError, got %T", err)
+	}
+	if conflict.ExistingPath != "/users/:id" {
+		t.Errorf("ExistingPath = %q, want %q", conflict.ExistingPath, "/users/:id")
+	}
+}
+
+func TestRadixNodeInsertConflictStaticThenParam(t *testing.T) {
+	root := newRadixNode()
+
+	if err := root.insert(GET, "/users/me", splitSegments("/users/me"), noopHandler, nil); err != nil {
+		t.Fatalf("unexpected error registering /users/me: %v", err)
+	}
+
+	err := root.insert(GET, "/users/:id", splitSegments("/users/:id"), noopHandler, nil)
+	if err == nil {
+		t.Fatal("expected a conflict registering /users/:id alongside /users/me")
+	}
+	conflict, ok := err.(*RouteConflictError)
+	if !ok {
+		t.Fatalf("expected *RouteConflictError, got %T", err)
+	}
+	if conflict.ExistingPath != "/users/me" {
+		t.Errorf("ExistingPath = %q, want %q", conflict.ExistingPath, "/users/me")
+	}
+}
+
+// TestRadixNodeInsertConflictThroughParamBranch covers a route reached only
+// through an intermediate :param node that never got its own routePath (only
+// the leaf /users/:id/profile does): the conflict must still name that leaf,
+// not "".
+func TestRadixNodeInsertConflictThroughParamBranch(t *testing.T) {
+	root := newRadixNode()
+
+	if err := root.insert(GET, "/users/:id/profile", splitSegments("/users/:id/profile"), noopHandler, nil); err != nil {
+		t.Fatalf("unexpected error registering /users/:id/profile: %v", err)
+	}
+
+	err := root.insert(GET, "/users/me/settings", splitSegments("/users/me/settings"), noopHandler, nil)
+	if err == nil {
+		t.Fatal("expected a conflict registering /users/me/settings alongside /users/:id/profile")
+	}
+	conflict, ok := err.(*RouteConflictError)
+	if !ok {
+		t.Fatalf("expected *RouteConflictError, got %T", err)
+	}
+	if conflict.ExistingPath == "" {
+		t.Error("ExistingPath is empty; should name the route reachable through the :id branch")
+	}
+	if conflict.ExistingPath != "/users/:id/profile" {
+		t.Errorf("ExistingPath = %q, want %q", conflict.ExistingPath, "/users/:id/profile")
+	}
+}
+
+func TestRadixNodeInsertConflictMismatchedParamNames(t *testing.T) {
+	root := newRadixNode()
+
+	if err := root.insert(GET, "/users/:id", splitSegments("/users/:id"), noopHandler, nil); err != nil {
+		t.Fatalf("unexpected error registering /users/:id: %v", err)
+	}
+
+	err := root.insert(GET, "/users/:userID", splitSegments("/users/:userID"), noopHandler, nil)
+	if err == nil {
+		t.Fatal("expected a conflict registering /users/:userID alongside /users/:id")
+	}
+	conflict, ok := err.(*RouteConflictError)
+	if !ok {
+		t.Fatalf("expected *RouteConflictError, got %T", err)
+	}
+	if conflict.ExistingPath != "/users/:id" {
+		t.Errorf("ExistingPath = %q, want %q", conflict.ExistingPath, "/users/:id")
+	}
+}
+
+func TestRadixNodeInsertDuplicateRoute(t *testing.T) {
+	root := newRadixNode()
+
+	if err := root.insert(GET, "/users/:id", splitSegments("/users/:id"), noopHandler, nil); err != nil {
+		t.Fatalf("unexpected error registering /users/:id: %v", err)
+	}
+	if err := root.insert(GET, "/users/:id", splitSegments("/users/:id"), noopHandler, nil); err == nil {
+		t.Fatal("expected a conflict re-registering the same route")
+	}
+}
+
+func TestRadixNodeLookupTrailingSlash(t *testing.T) {
+	root := newRadixNode()
+	if err := root.insert(GET, "/users/:id", splitSegments("/users/:id"), noopHandler, nil); err != nil {
+		t.Fatalf("unexpected error registering /users/:id: %v", err)
+	}
+
+	for _, path := range []string{"/users/42", "/users/42/", "users/42"} {
+		node, params, ok := root.lookup(splitSegments(path))
+		if !ok {
+			t.Errorf("lookup(%q): expected a match", path)
+			continue
+		}
+		if node.handler == nil {
+			t.Errorf("lookup(%q): matched node has no handler", path)
+		}
+		if params["id"] != "42" {
+			t.Errorf("lookup(%q): params[id] = %q, want %q", path, params["id"], "42")
+		}
+	}
+}
+
+func TestRadixNodeLookupNoMatch(t *testing.T) {
+	root := newRadixNode()
+	if err := root.insert(GET, "/users/:id", splitSegments("/users/:id"), noopHandler, nil); err != nil {
+		t.Fatalf("unexpected error registering /users/:id: %v", err)
+	}
+
+	if _, _, ok := root.lookup(splitSegments("/users/42/profile")); ok {
+		t.Error("lookup matched a longer path than any registered route")
+	}
+	if _, _, ok := root.lookup(splitSegments("/accounts/42")); ok {
+		t.Error("lookup matched a completely unrelated path")
+	}
+}